@@ -0,0 +1,33 @@
+package jwtauth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+)
+
+// TestEd25519_RoundTrip guards against New/Encode/Decode's Ed25519 claim
+// being false: golang-jwt's SigningMethodEd25519 type-asserts its key to
+// ed25519.PrivateKey/ed25519.PublicKey, which a raw []byte-typed
+// interface{} value never satisfies.
+func TestEd25519_RoundTrip(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ja := New("EdDSA", priv, pub)
+
+	_, tokenString, err := ja.Encode(map[string]interface{}{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("Encode with EdDSA key: %v", err)
+	}
+
+	token, err := ja.Decode(tokenString)
+	if err != nil {
+		t.Fatalf("Decode with EdDSA key: %v", err)
+	}
+	if !token.Valid {
+		t.Fatal("token not valid")
+	}
+}
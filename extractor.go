@@ -0,0 +1,122 @@
+package jwtauth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TokenExtractor pulls a raw token string out of an incoming request,
+// returning "" if it isn't present. A JwtAuth tries its configured
+// extractors in order and uses the first non-empty result.
+type TokenExtractor func(r *http.Request) string
+
+// FromQuery returns a TokenExtractor that reads the token from the named
+// URL query parameter, e.g. FromQuery("jwt") for "?jwt=...".
+func FromQuery(name string) TokenExtractor {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(name)
+	}
+}
+
+// FromHeader returns a TokenExtractor that reads the token from the named
+// request header, stripping the given auth scheme prefix (matched
+// case-insensitively), e.g. FromHeader("Authorization", "Bearer").
+func FromHeader(name, scheme string) TokenExtractor {
+	prefix := scheme + " "
+	return func(r *http.Request) string {
+		v := r.Header.Get(name)
+		if len(v) <= len(prefix) || !strings.EqualFold(v[:len(prefix)], prefix) {
+			return ""
+		}
+		return v[len(prefix):]
+	}
+}
+
+// FromCookie returns a TokenExtractor that reads the token from the named
+// cookie.
+func FromCookie(name string) TokenExtractor {
+	return func(r *http.Request) string {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return cookie.Value
+	}
+}
+
+// FromFormField returns a TokenExtractor that reads the token from the
+// named field of a parsed form body (application/x-www-form-urlencoded or
+// multipart/form-data), falling back to a query parameter of the same name
+// per http.Request.FormValue.
+func FromFormField(name string) TokenExtractor {
+	return func(r *http.Request) string {
+		return r.FormValue(name)
+	}
+}
+
+// defaultExtractors is the extraction chain used when a JwtAuth has no
+// extractors configured via WithExtractors: query param "jwt", then each
+// non-empty paramAliases query param in order, then Authorization: Bearer,
+// then cookie "jwt" — preserved for backwards
+// compatibility with callers built against earlier versions of this
+// package.
+func defaultExtractors(paramAliases ...string) []TokenExtractor {
+	extractors := []TokenExtractor{FromQuery("jwt")}
+	for _, alias := range paramAliases {
+		if alias != "" {
+			extractors = append(extractors, FromQuery(alias))
+		}
+	}
+	extractors = append(extractors, FromHeader("Authorization", "Bearer"), FromCookie("jwt"))
+	return extractors
+}
+
+func extractToken(r *http.Request, extractors []TokenExtractor) string {
+	for _, extract := range extractors {
+		if tok := extract(r); tok != "" {
+			return tok
+		}
+	}
+	return ""
+}
+
+// WithExtractors replaces the default token extraction chain (query "jwt",
+// Authorization: Bearer, cookie "jwt") with extractors, tried in order.
+func WithExtractors(extractors ...TokenExtractor) Option {
+	return func(ja *JwtAuth) {
+		ja.extractors = extractors
+	}
+}
+
+// ErrorHandler responds to a request whose token failed extraction or
+// validation. err is errUnauthorized-equivalent; implementations may
+// inspect it to vary the response (e.g. a different error_description),
+// but it is not part of this package's exported API.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// WithErrorHandler overrides how a JwtAuth responds to unauthorized
+// requests. The default, used when this option isn't set, writes a 401
+// with a plain-text "unauthorized token" body.
+func WithErrorHandler(fn ErrorHandler) Option {
+	return func(ja *JwtAuth) {
+		ja.errorHandler = fn
+	}
+}
+
+func defaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, errUnauthorized.Error(), http.StatusUnauthorized)
+}
+
+// ChallengeErrorHandler returns an ErrorHandler that responds per RFC 6750
+// section 3: a 401 with a WWW-Authenticate: Bearer challenge carrying
+// realm, error="invalid_token" and an error_description taken from err,
+// alongside the same plain-text body the default handler writes.
+func ChallengeErrorHandler(realm string) ErrorHandler {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+			`Bearer realm=%q, error="invalid_token", error_description=%q`,
+			realm, err.Error()))
+		http.Error(w, errUnauthorized.Error(), http.StatusUnauthorized)
+	}
+}
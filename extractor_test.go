@@ -0,0 +1,57 @@
+package jwtauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestFromHeader guards FromHeader's case-insensitive scheme-prefix
+// matching and stripping.
+func TestFromHeader(t *testing.T) {
+	extract := FromHeader("Authorization", "Bearer")
+
+	cases := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"exact scheme", "Bearer abc.def.ghi", "abc.def.ghi"},
+		{"case-insensitive scheme", "bearer abc.def.ghi", "abc.def.ghi"},
+		{"missing scheme", "abc.def.ghi", ""},
+		{"wrong scheme", "Basic abc.def.ghi", ""},
+		{"empty header", "", ""},
+		{"scheme with no token", "Bearer", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if c.header != "" {
+				r.Header.Set("Authorization", c.header)
+			}
+			if got := extract(r); got != c.want {
+				t.Fatalf("FromHeader(%q) = %q, want %q", c.header, got, c.want)
+			}
+		})
+	}
+}
+
+// TestChallengeErrorHandler guards the RFC 6750 section 3 WWW-Authenticate
+// challenge format ChallengeErrorHandler writes.
+func TestChallengeErrorHandler(t *testing.T) {
+	handler := ChallengeErrorHandler("test-realm")
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	handler(rw, r, errUnauthorized)
+
+	if rw.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusUnauthorized)
+	}
+
+	want := `Bearer realm="test-realm", error="invalid_token", error_description="unauthorized token"`
+	if got := rw.Header().Get("WWW-Authenticate"); got != want {
+		t.Fatalf("WWW-Authenticate = %q, want %q", got, want)
+	}
+}
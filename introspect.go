@@ -0,0 +1,289 @@
+package jwtauth
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultIntrospectionCacheSize bounds the number of recent introspection
+// responses kept in memory when WithIntrospectionCacheSize isn't set.
+const DefaultIntrospectionCacheSize = 4096
+
+// DefaultIntrospectionMaxTTL caps how long a cached introspection response
+// is trusted, even if the token's own "exp" claims a longer lifetime.
+const DefaultIntrospectionMaxTTL = 5 * time.Minute
+
+var (
+	errTokenNotActive  = fmt.Errorf("jwtauth: token introspection reported inactive token")
+	errScopeNotGranted = fmt.Errorf("jwtauth: token introspection response missing required scope")
+)
+
+// introspector verifies opaque bearer tokens against an RFC 7662 OAuth 2.0
+// token introspection endpoint instead of validating a local signature.
+// Responses are cached in memory, keyed by a hash of the token, for a TTL
+// bounded by the response's own "exp" so repeated requests on a hot path
+// don't round-trip to the issuer every time.
+type introspector struct {
+	endpoint      string
+	clientID      string
+	clientSecret  string
+	client        *http.Client
+	maxTTL        time.Duration
+	requiredScope string
+
+	mu        sync.Mutex
+	cache     map[string]*list.Element // token hash -> entry in lru
+	lru       *list.List
+	cacheSize int
+}
+
+type introspectionCacheEntry struct {
+	tokenHash string
+	claims    map[string]interface{}
+	expiresAt time.Time
+}
+
+// introspectionResponse is the subset of RFC 7662's response fields this
+// module understands.
+type introspectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope"`
+	ClientID  string `json:"client_id"`
+	Username  string `json:"username"`
+	TokenType string `json:"token_type"`
+	Exp       int64  `json:"exp"`
+	Iat       int64  `json:"iat"`
+	Nbf       int64  `json:"nbf"`
+	Sub       string `json:"sub"`
+	// Aud is raw JSON rather than string because RFC 7662 leaves "aud"'s
+	// shape to the claim it mirrors, and per RFC 7519 section 4.1.3 some
+	// IdPs report it as an array of strings rather than a bare string.
+	Aud json.RawMessage `json:"aud"`
+	Iss string          `json:"iss"`
+}
+
+// WithIntrospectionCacheSize overrides how many introspection responses a
+// JwtAuth built via NewIntrospector keeps cached at once, evicting the
+// least recently used entry once full. It has no effect on other
+// constructors.
+func WithIntrospectionCacheSize(n int) Option {
+	return func(ja *JwtAuth) {
+		if ja.introspector != nil {
+			ja.introspector.cacheSize = n
+		}
+	}
+}
+
+// WithIntrospectionMaxTTL caps how long an introspection response is
+// trusted before the token is re-introspected, regardless of the "exp" the
+// issuer reported. It has no effect on other constructors.
+func WithIntrospectionMaxTTL(d time.Duration) Option {
+	return func(ja *JwtAuth) {
+		if ja.introspector != nil {
+			ja.introspector.maxTTL = d
+		}
+	}
+}
+
+// WithScope rejects introspected tokens whose space-separated "scope"
+// claim (RFC 7662 section 2.2) doesn't include scope. It has no effect on
+// other constructors.
+func WithScope(scope string) Option {
+	return func(ja *JwtAuth) {
+		if ja.introspector != nil {
+			ja.introspector.requiredScope = scope
+		}
+	}
+}
+
+// NewIntrospector creates a JwtAuth that authorizes presented bearer tokens
+// by calling an RFC 7662 token introspection endpoint instead of verifying
+// a local JWT signature, for IdPs that issue opaque tokens. A token is
+// accepted iff the introspection response reports "active": true and
+// satisfies any WithIssuer/WithAudience/WithScope constraint configured via
+// opts.
+func NewIntrospector(endpoint string, clientID, clientSecret string, opts ...Option) *JwtAuth {
+	ja := &JwtAuth{
+		introspector: &introspector{
+			endpoint:     endpoint,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			client:       http.DefaultClient,
+			maxTTL:       DefaultIntrospectionMaxTTL,
+			cache:        make(map[string]*list.Element),
+			lru:          list.New(),
+			cacheSize:    DefaultIntrospectionCacheSize,
+		},
+	}
+	for _, opt := range opts {
+		opt(ja)
+	}
+	return ja
+}
+
+// authorize returns the introspected claims for tokenString, consulting the
+// cache first, and enforces ja.validator's issuer/audience/leeway rules,
+// any configured WithScope requirement, and any WithClaimValidator
+// predicate against them.
+func (ja *JwtAuth) authorize(tokenString string) (map[string]interface{}, error) {
+	claims, err := ja.introspector.claimsFor(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if err := ja.validator.validate(claims, EpochNow()); err != nil {
+		return nil, err
+	}
+	if required := ja.introspector.requiredScope; required != "" {
+		scope, _ := claims["scope"].(string)
+		if !scopeContains(scope, required) {
+			return nil, errScopeNotGranted
+		}
+	}
+	return claims, nil
+}
+
+// scopeContains reports whether want is one of the space-separated scopes
+// in scope, per RFC 7662 section 2.2.
+func scopeContains(scope, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+func (in *introspector) claimsFor(tokenString string) (map[string]interface{}, error) {
+	hash := hashToken(tokenString)
+
+	in.mu.Lock()
+	if el, ok := in.cache[hash]; ok {
+		entry := el.Value.(*introspectionCacheEntry)
+		if time.Now().Before(entry.expiresAt) {
+			in.lru.MoveToFront(el)
+			claims := entry.claims
+			in.mu.Unlock()
+			return claims, nil
+		}
+		in.lru.Remove(el)
+		delete(in.cache, hash)
+	}
+	in.mu.Unlock()
+
+	resp, err := in.introspect(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Active {
+		return nil, errTokenNotActive
+	}
+
+	claims := resp.claims()
+	in.store(hash, claims, resp.Exp)
+	return claims, nil
+}
+
+func (in *introspector) store(hash string, claims map[string]interface{}, exp int64) {
+	ttl := in.maxTTL
+	if exp > 0 {
+		if untilExp := time.Unix(exp, 0).Sub(time.Now()); untilExp < ttl {
+			ttl = untilExp
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	el := in.lru.PushFront(&introspectionCacheEntry{
+		tokenHash: hash,
+		claims:    claims,
+		expiresAt: time.Now().Add(ttl),
+	})
+	in.cache[hash] = el
+
+	for in.lru.Len() > in.cacheSize {
+		oldest := in.lru.Back()
+		if oldest == nil {
+			break
+		}
+		in.lru.Remove(oldest)
+		delete(in.cache, oldest.Value.(*introspectionCacheEntry).tokenHash)
+	}
+}
+
+func (in *introspector) introspect(tokenString string) (*introspectionResponse, error) {
+	form := url.Values{"token": {tokenString}}
+	req, err := http.NewRequest(http.MethodPost, in.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(in.clientID, in.clientSecret)
+
+	resp, err := in.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwtauth: introspection endpoint returned status %d", resp.StatusCode)
+	}
+
+	var ir introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ir); err != nil {
+		return nil, err
+	}
+	return &ir, nil
+}
+
+func (r *introspectionResponse) claims() map[string]interface{} {
+	claims := make(map[string]interface{})
+	if r.Exp != 0 {
+		claims["exp"] = r.Exp
+	}
+	if r.Iat != 0 {
+		claims["iat"] = r.Iat
+	}
+	if r.Nbf != 0 {
+		claims["nbf"] = r.Nbf
+	}
+	if r.Sub != "" {
+		claims["sub"] = r.Sub
+	}
+	if len(r.Aud) > 0 {
+		var aud interface{}
+		if err := json.Unmarshal(r.Aud, &aud); err == nil {
+			claims["aud"] = aud
+		}
+	}
+	if r.Iss != "" {
+		claims["iss"] = r.Iss
+	}
+	if r.Scope != "" {
+		claims["scope"] = r.Scope
+	}
+	if r.ClientID != "" {
+		claims["client_id"] = r.ClientID
+	}
+	if r.Username != "" {
+		claims["username"] = r.Username
+	}
+	return claims
+}
+
+func hashToken(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
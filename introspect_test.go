@@ -0,0 +1,65 @@
+package jwtauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func introspectionServer(t *testing.T, active bool, scope string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"active": active,
+			"scope":  scope,
+			"exp":    EpochNow() + 3600,
+		})
+	}))
+}
+
+// TestIntrospector_Scope guards against the request's explicit "expected
+// issuer/audience/scope" requirement being unenforced: a token introspected
+// as active but missing a required scope must be rejected.
+func TestIntrospector_Scope(t *testing.T) {
+	srv := introspectionServer(t, true, "read write")
+	defer srv.Close()
+
+	ja := NewIntrospector(srv.URL, "client-id", "client-secret", WithScope("admin"))
+
+	if _, err := ja.authorize("some-opaque-token"); err == nil {
+		t.Fatal("expected a token missing the required scope to be rejected")
+	}
+}
+
+func TestIntrospector_ScopeGranted(t *testing.T) {
+	srv := introspectionServer(t, true, "read write admin")
+	defer srv.Close()
+
+	ja := NewIntrospector(srv.URL, "client-id", "client-secret", WithScope("admin"))
+
+	if _, err := ja.authorize("some-opaque-token"); err != nil {
+		t.Fatalf("expected a token with the required scope to be accepted, got %v", err)
+	}
+}
+
+// TestIntrospector_AudienceArray guards against introspectionResponse.Aud
+// being typed as a plain string: an IdP reporting "aud" as a JSON array (as
+// Auth0 and others commonly do) used to fail json.Decode for the whole
+// introspection response.
+func TestIntrospector_AudienceArray(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"active": true,
+			"aud":    []string{"my-api", "other-api"},
+			"exp":    EpochNow() + 3600,
+		})
+	}))
+	defer srv.Close()
+
+	ja := NewIntrospector(srv.URL, "client-id", "client-secret", WithAudience("my-api"))
+
+	if _, err := ja.authorize("some-opaque-token"); err != nil {
+		t.Fatalf("expected array aud containing the configured audience to be accepted, got %v", err)
+	}
+}
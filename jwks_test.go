@@ -0,0 +1,70 @@
+package jwtauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pressly/chi"
+	"golang.org/x/net/context"
+)
+
+// TestHandle_JWKS drives the public Handle()/Handler() entry point end to
+// end against a JWKS server and an RSA-signed, kid-matching token, to guard
+// against the Handle() signer-identity check rejecting every JWKS-verified
+// request (it used to compare token.Method against a never-set ja.signer).
+func TestHandle_JWKS(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]interface{}{
+				{
+					"kty": "RSA",
+					"kid": "test-kid",
+					"n":   base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()),
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	ja := NewFromJWKS(srv.URL, WithJWKSRefreshInterval(time.Hour))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "test-kid"
+	signed, err := token.SignedString(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var called bool
+	next := chi.HandlerFunc(func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/?jwt="+signed, nil)
+	rw := httptest.NewRecorder()
+
+	ja.Handler(next).ServeHTTPC(context.Background(), rw, req)
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Handle() rejected a valid JWKS-verified token: status %d, body %q", rw.Code, rw.Body.String())
+	}
+	if !called {
+		t.Fatal("Handle() did not call next for a valid JWKS-verified token")
+	}
+}
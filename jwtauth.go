@@ -1,12 +1,12 @@
 package jwtauth
 
 import (
+	"crypto/ed25519"
 	"errors"
 	"net/http"
-	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/pressly/chi"
 	"golang.org/x/net/context"
 )
@@ -20,80 +20,117 @@ type JwtAuth struct {
 	verifyKey []byte
 	signer    jwt.SigningMethod
 	parser    *jwt.Parser
+
+	// jwks is set by NewFromJWKS; when non-nil, keyFunc resolves the
+	// verifying key per-token from the remote key set instead of verifyKey.
+	jwks                *jwksSource
+	jwksRefreshInterval time.Duration
+
+	// defs is set by NewMulti; when non-nil, Decode tries each in turn
+	// instead of using signer/keyFunc directly.
+	defs []TokenDef
+
+	// introspector is set by NewIntrospector; when non-nil, Handle
+	// authorizes tokens via RFC 7662 introspection instead of local JWT
+	// verification.
+	introspector *introspector
+
+	// extractors overrides the default token extraction chain when set via
+	// WithExtractors.
+	extractors []TokenExtractor
+
+	// errorHandler overrides how unauthorized requests are responded to
+	// when set via WithErrorHandler.
+	errorHandler ErrorHandler
+
+	validator Validator
+}
+
+// Option configures a JwtAuth built via a New* constructor that accepts
+// variadic options, such as NewFromJWKS.
+type Option func(*JwtAuth)
+
+// WithJWKSRefreshInterval overrides how often a JwtAuth built via
+// NewFromJWKS proactively refreshes its cached key set. It has no effect on
+// constructors that don't use a JWKS.
+func WithJWKSRefreshInterval(d time.Duration) Option {
+	return func(ja *JwtAuth) {
+		ja.jwksRefreshInterval = d
+	}
 }
 
 // verifyKey is only for RSA
-func New(alg string, signKey []byte, verifyKey []byte) *JwtAuth {
-	return &JwtAuth{
+func New(alg string, signKey []byte, verifyKey []byte, opts ...Option) *JwtAuth {
+	ja := &JwtAuth{
 		signKey:   signKey,
 		verifyKey: verifyKey,
 		signer:    jwt.GetSigningMethod(alg),
 	}
+	for _, opt := range opts {
+		opt(ja)
+	}
+	return ja
 }
 
-// the same as New, except it supports custom parser settings introduced in ver. 2.4.0 of jwt-go
-func NewWithParser(alg string, parser *jwt.Parser, signKey []byte, verifyKey []byte) *JwtAuth {
-	return &JwtAuth{
+// the same as New, except it accepts a *jwt.Parser configured with custom
+// parser options (jwt.WithValidMethods, jwt.WithoutClaimsValidation, etc.)
+func NewWithParser(alg string, parser *jwt.Parser, signKey []byte, verifyKey []byte, opts ...Option) *JwtAuth {
+	ja := &JwtAuth{
 		signKey:   signKey,
 		verifyKey: verifyKey,
 		signer:    jwt.GetSigningMethod(alg),
 		parser:    parser,
 	}
+	for _, opt := range opts {
+		opt(ja)
+	}
+	return ja
 }
 
 func (ja *JwtAuth) Handle(paramAliases ...string) func(chi.Handler) chi.Handler {
 	return func(next chi.Handler) chi.Handler {
 		hfn := func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 
-			var tokenStr string
-			var err error
-
-			// Get token from query params
-			tokenStr = r.URL.Query().Get("jwt")
-
-			// Get token from other query param aliases
-			if tokenStr == "" && paramAliases != nil && len(paramAliases) > 0 {
-				for _, p := range paramAliases {
-					tokenStr = r.URL.Query().Get(p)
-					if tokenStr != "" {
-						break
-					}
-				}
+			extractors := ja.extractors
+			if extractors == nil {
+				extractors = defaultExtractors(paramAliases...)
 			}
+			tokenStr := extractToken(r, extractors)
 
-			// Get token from authorization header
-			if tokenStr == "" {
-				bearer := r.Header.Get("Authorization")
-				if len(bearer) > 7 && strings.ToUpper(bearer[0:6]) == "BEARER" {
-					tokenStr = bearer[7:]
-				}
+			errorHandler := ja.errorHandler
+			if errorHandler == nil {
+				errorHandler = defaultErrorHandler
 			}
 
-			// Get token from cookie
+			// Token is required, cya
 			if tokenStr == "" {
-				cookie, err := r.Cookie("jwt")
-				if err == nil {
-					tokenStr = cookie.Value
-				}
+				errorHandler(w, r, errUnauthorized)
+				return
 			}
 
-			// Token is required, cya
-			if tokenStr == "" {
-				err = errUnauthorized
+			if ja.introspector != nil {
+				claims, err := ja.authorize(tokenStr)
+				if err != nil {
+					errorHandler(w, r, err)
+					return
+				}
+				ctx = context.WithValue(ctx, "jwt", tokenStr)
+				ctx = context.WithValue(ctx, "jwt.claims", claims)
+				next.ServeHTTPC(ctx, w, r)
+				return
 			}
 
 			// Verify the token
 			token, err := ja.Decode(tokenStr)
-			if err != nil || !token.Valid || token.Method != ja.signer {
-				http.Error(w, errUnauthorized.Error(), 401)
+			if err != nil || !token.Valid || (ja.jwks == nil && ja.defs == nil && token.Method != ja.signer) {
+				errorHandler(w, r, errUnauthorized)
 				return
 			}
 
-			// Check expiry via "exp" claim
-			if exp, ok := token.Claims["exp"].(int64); ok {
-				now := EpochNow()
-				if exp < now {
-					http.Error(w, errUnauthorized.Error(), 401)
+			// Check exp/nbf/iat/iss/aud (and any user-supplied predicate)
+			if claims, ok := token.Claims.(jwt.MapClaims); ok {
+				if err := ja.validator.validate(claims, EpochNow()); err != nil {
+					errorHandler(w, r, err)
 					return
 				}
 			}
@@ -111,27 +148,114 @@ func (ja *JwtAuth) Handler(next chi.Handler) chi.Handler {
 	return ja.Handle("")(next)
 }
 
+// Encode signs claims as a jwt.MapClaims token. It is kept for backwards
+// compatibility; new code should prefer EncodeClaims with a typed
+// jwt.Claims struct.
 func (ja *JwtAuth) Encode(claims map[string]interface{}) (t *jwt.Token, tokenString string, err error) {
-	t = jwt.New(ja.signer)
-	t.Claims = claims
-	tokenString, err = t.SignedString(ja.signKey)
+	return ja.EncodeClaims(jwt.MapClaims(claims))
+}
+
+// EncodeClaims signs claims, which may be jwt.MapClaims or any struct
+// satisfying jwt.Claims (e.g. embedding jwt.RegisteredClaims).
+func (ja *JwtAuth) EncodeClaims(claims jwt.Claims) (t *jwt.Token, tokenString string, err error) {
+	t = jwt.NewWithClaims(ja.signer, claims)
+	tokenString, err = t.SignedString(ja.signingKey())
 	t.Raw = tokenString
 	return
 }
 
+// signingKey returns signKey in the type ja.signer's Sign expects. HMAC
+// takes the raw secret bytes; RSA, RSA-PSS and ECDSA take a
+// *rsa.PrivateKey/*ecdsa.PrivateKey, parsed here from a PEM-encoded
+// signKey; SigningMethodEd25519 type-asserts its key to
+// ed25519.PrivateKey, which a []byte never satisfies. A signKey that fails
+// to parse is returned as is, and signing then fails.
+func (ja *JwtAuth) signingKey() interface{} {
+	if ja.signer == jwt.SigningMethodEdDSA {
+		return ed25519.PrivateKey(ja.signKey)
+	}
+	switch ja.signer.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if k, err := jwt.ParseRSAPrivateKeyFromPEM(ja.signKey); err == nil {
+			return k
+		}
+	case *jwt.SigningMethodECDSA:
+		if k, err := jwt.ParseECPrivateKeyFromPEM(ja.signKey); err == nil {
+			return k
+		}
+	}
+	return ja.signKey
+}
+
+// verifyingKey is signingKey's counterpart for the key keyFunc hands back
+// to verify a signature.
+func (ja *JwtAuth) verifyingKey(keyBytes []byte) interface{} {
+	return resolveVerifyKey(ja.signer, keyBytes)
+}
+
+// resolveVerifyKey converts keyBytes into the type signer's Verify expects.
+// HMAC verifies against the raw key bytes; RSA, RSA-PSS and ECDSA verify
+// against a *rsa.PublicKey/*ecdsa.PublicKey, parsed here from PEM-encoded
+// keyBytes; SigningMethodEd25519 type-asserts its key to
+// ed25519.PublicKey, which a []byte never satisfies. keyBytes that fail to
+// parse are returned as is, and verification then fails. Shared with
+// multi.go's decodeMulti, which resolves a verify key per TokenDef rather
+// than per JwtAuth.
+func resolveVerifyKey(signer jwt.SigningMethod, keyBytes []byte) interface{} {
+	if signer == jwt.SigningMethodEdDSA {
+		return ed25519.PublicKey(keyBytes)
+	}
+	switch signer.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if k, err := jwt.ParseRSAPublicKeyFromPEM(keyBytes); err == nil {
+			return k
+		}
+	case *jwt.SigningMethodECDSA:
+		if k, err := jwt.ParseECPublicKeyFromPEM(keyBytes); err == nil {
+			return k
+		}
+	}
+	return keyBytes
+}
+
 func (ja *JwtAuth) keyFunc(t *jwt.Token) (interface{}, error) {
+	if ja.jwks != nil {
+		return ja.jwksKeyFunc(t)
+	}
 	if ja.verifyKey != nil && len(ja.verifyKey) > 0 {
-		return ja.verifyKey, nil
+		return ja.verifyingKey(ja.verifyKey), nil
 	} else {
-		return ja.signKey, nil
+		return ja.verifyingKey(ja.signKey), nil
 	}
 }
 
+// Decode parses and verifies tokenString's signature. It deliberately skips
+// golang-jwt's own exp/nbf validation (via jwt.WithoutClaimsValidation()) so
+// that ja.validator, not the library's zero-leeway default, is the single
+// source of truth for those checks; callers that need the claims validated
+// should run them through ja.validator.validate, as Handle does. A caller
+// using NewWithParser supplies its own *jwt.Parser and opts into whatever
+// validation it's configured with instead.
 func (ja *JwtAuth) Decode(tokenString string) (t *jwt.Token, err error) {
+	if ja.defs != nil {
+		return ja.decodeMulti(tokenString)
+	}
 	if ja.parser != nil {
 		return ja.parser.Parse(tokenString, ja.keyFunc)
 	}
-	return jwt.Parse(tokenString, ja.keyFunc)
+	return jwt.Parse(tokenString, ja.keyFunc, jwt.WithoutClaimsValidation())
+}
+
+// DecodeInto parses tokenString the same way as Decode, but unmarshals its
+// claims into the caller-supplied claims (typically a struct embedding
+// jwt.RegisteredClaims) instead of a jwt.MapClaims. It verifies through
+// keyFunc only: the TokenDefs of a JwtAuth built by NewMulti are not
+// consulted.
+func (ja *JwtAuth) DecodeInto(tokenString string, claims jwt.Claims) (t *jwt.Token, err error) {
+	if ja.parser != nil {
+		return ja.parser.ParseWithClaims(tokenString, claims, ja.keyFunc)
+	}
+	return jwt.ParseWithClaims(tokenString, claims, ja.keyFunc, jwt.WithoutClaimsValidation())
 }
 
 // Return the NumericDate time value used in conventional jwt claims
@@ -0,0 +1,29 @@
+package jwtauth
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TestDecode_Leeway guards against WithLeeway being a no-op: golang-jwt's
+// own parser-level default claims validation runs inside jwt.Parse before
+// ja.validator ever sees the token, and it always applies zero leeway, so an
+// expired-but-within-leeway token used to be rejected by Decode itself
+// regardless of WithLeeway.
+func TestDecode_Leeway(t *testing.T) {
+	key := []byte("secret")
+	ja := New("HS256", key, nil, WithLeeway(30))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp": EpochNow() - 10,
+	})
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ja.Decode(tokenString); err != nil {
+		t.Fatalf("expected a token expired 10s ago with a 30s leeway to decode, got %v", err)
+	}
+}
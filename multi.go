@@ -0,0 +1,78 @@
+package jwtauth
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenDef describes one of several key/algorithm pairs a multi-token
+// JwtAuth (see NewMulti) will accept. Issuer and Audience, when set,
+// restrict this definition to tokens carrying matching "iss"/"aud" claims,
+// which lets a single middleware instance accept tokens from several
+// trusted issuers at once.
+type TokenDef struct {
+	Alg       string
+	SignKey   []byte
+	VerifyKey []byte
+	Issuer    string
+	Audience  string
+
+	signer jwt.SigningMethod
+}
+
+// NewMulti creates a JwtAuth that accepts tokens signed under any of defs,
+// enabling zero-downtime key rotation: add the new TokenDef alongside the
+// old one, start issuing tokens with it, and drop the old TokenDef once
+// every outstanding token has expired. During Decode, defs are tried in
+// order; the first one whose algorithm, signature and issuer/audience
+// constraints all match wins.
+func NewMulti(defs []TokenDef) *JwtAuth {
+	resolved := make([]TokenDef, len(defs))
+	for i, d := range defs {
+		d.signer = jwt.GetSigningMethod(d.Alg)
+		resolved[i] = d
+	}
+	return &JwtAuth{defs: resolved}
+}
+
+// matchesConstraints reports whether claims satisfies d's optional
+// Issuer/Audience restriction.
+func (d TokenDef) matchesConstraints(claims jwt.MapClaims) bool {
+	if d.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != d.Issuer {
+			return false
+		}
+	}
+	if d.Audience != "" && !audienceContains(claims["aud"], d.Audience) {
+		return false
+	}
+	return true
+}
+
+func (ja *JwtAuth) decodeMulti(tokenString string) (t *jwt.Token, err error) {
+	var lastErr error
+	for _, d := range ja.defs {
+		parsed, parseErr := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+			if t.Method != d.signer {
+				return nil, errUnauthorized
+			}
+			if d.VerifyKey != nil && len(d.VerifyKey) > 0 {
+				return resolveVerifyKey(d.signer, d.VerifyKey), nil
+			}
+			return resolveVerifyKey(d.signer, d.SignKey), nil
+		}, jwt.WithoutClaimsValidation())
+		if parseErr != nil {
+			lastErr = parseErr
+			continue
+		}
+		claims, ok := parsed.Claims.(jwt.MapClaims)
+		if !ok || !d.matchesConstraints(claims) {
+			lastErr = errUnauthorized
+			continue
+		}
+		return parsed, nil
+	}
+	if lastErr == nil {
+		lastErr = errUnauthorized
+	}
+	return nil, lastErr
+}
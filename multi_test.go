@@ -0,0 +1,50 @@
+package jwtauth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TestTokenDef_AudienceArray guards against TokenDef.Audience rejecting
+// tokens whose "aud" claim is a JSON array rather than a bare string.
+func TestTokenDef_AudienceArray(t *testing.T) {
+	d := TokenDef{Audience: "my-api"}
+
+	if !d.matchesConstraints(jwt.MapClaims{"aud": []interface{}{"my-api", "other-api"}}) {
+		t.Fatal("expected array aud containing the configured audience to match")
+	}
+	if d.matchesConstraints(jwt.MapClaims{"aud": []interface{}{"other-api"}}) {
+		t.Fatal("expected array aud not containing the configured audience to not match")
+	}
+}
+
+// TestNewMulti_Ed25519 guards against decodeMulti's inline keyFunc handing
+// raw []byte straight to golang-jwt's SigningMethodEd25519, which type-
+// asserts its key to ed25519.PublicKey and rejects anything else.
+func TestNewMulti_Ed25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ja := NewMulti([]TokenDef{{Alg: "EdDSA", VerifyKey: pub}})
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"exp": EpochNow() + 3600,
+	})
+	tokenString, err := token.SignedString(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ja.Decode(tokenString)
+	if err != nil {
+		t.Fatalf("Decode with an Ed25519 TokenDef: %v", err)
+	}
+	if !parsed.Valid {
+		t.Fatal("token not valid")
+	}
+}
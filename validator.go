@@ -0,0 +1,178 @@
+package jwtauth
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultMaxTokenAge is the default iat-freshness window used when
+// WithMaxTokenAge is set to a value <= 0; it is not enforced unless
+// WithMaxTokenAge is configured.
+const DefaultMaxTokenAge = 60
+
+// Validator checks a token's claims beyond signature verification. The
+// zero Validator enforces "exp", "nbf" and "iat" when present, with no
+// leeway and no issuer/audience/freshness constraints.
+type Validator struct {
+	leeway         int64
+	issuer         string
+	audience       string
+	maxTokenAge    int64
+	claimValidator func(jwt.MapClaims) error
+}
+
+// WithLeeway allows clock skew of the given number of seconds when checking
+// exp/nbf/iat.
+func WithLeeway(seconds int64) Option {
+	return func(ja *JwtAuth) {
+		ja.validator.leeway = seconds
+	}
+}
+
+// WithIssuer rejects tokens whose "iss" claim isn't s.
+func WithIssuer(s string) Option {
+	return func(ja *JwtAuth) {
+		ja.validator.issuer = s
+	}
+}
+
+// WithAudience rejects tokens whose "aud" claim isn't s or an array
+// containing s.
+func WithAudience(s string) Option {
+	return func(ja *JwtAuth) {
+		ja.validator.audience = s
+	}
+}
+
+// WithMaxTokenAge rejects tokens whose "iat" claim is older than seconds,
+// similar to go-ethereum's engine API JWT handler. Useful for short-lived
+// machine-to-machine auth where a stale token is a sign of replay. Pass
+// DefaultMaxTokenAge for the conventional 60 second window.
+func WithMaxTokenAge(seconds int64) Option {
+	return func(ja *JwtAuth) {
+		if seconds <= 0 {
+			seconds = DefaultMaxTokenAge
+		}
+		ja.validator.maxTokenAge = seconds
+	}
+}
+
+// WithClaimValidator adds a user-supplied predicate run after the built-in
+// exp/nbf/iat/iss/aud checks pass. Returning a non-nil error rejects the
+// token.
+func WithClaimValidator(fn func(jwt.MapClaims) error) Option {
+	return func(ja *JwtAuth) {
+		ja.validator.claimValidator = fn
+	}
+}
+
+// validate runs v's checks against claims. now is EpochNow(), threaded in
+// so tests can control it.
+func (v Validator) validate(claims jwt.MapClaims, now int64) error {
+	if expVal, ok := claims["exp"]; ok {
+		exp, err := numericClaim(expVal)
+		if err != nil {
+			return fmt.Errorf("jwtauth: invalid exp claim: %w", err)
+		}
+		if now > exp+v.leeway {
+			return errUnauthorized
+		}
+	}
+
+	if nbfVal, ok := claims["nbf"]; ok {
+		nbf, err := numericClaim(nbfVal)
+		if err != nil {
+			return fmt.Errorf("jwtauth: invalid nbf claim: %w", err)
+		}
+		if now < nbf-v.leeway {
+			return errUnauthorized
+		}
+	}
+
+	var iat int64
+	if iatVal, ok := claims["iat"]; ok {
+		var err error
+		iat, err = numericClaim(iatVal)
+		if err != nil {
+			return fmt.Errorf("jwtauth: invalid iat claim: %w", err)
+		}
+		if now < iat-v.leeway {
+			return errUnauthorized
+		}
+	}
+
+	if v.maxTokenAge > 0 {
+		if iat == 0 {
+			return errUnauthorized
+		}
+		if now > iat+v.maxTokenAge+v.leeway {
+			return errUnauthorized
+		}
+	}
+
+	if v.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return errUnauthorized
+		}
+	}
+
+	if v.audience != "" {
+		if !audienceContains(claims["aud"], v.audience) {
+			return errUnauthorized
+		}
+	}
+
+	if v.claimValidator != nil {
+		if err := v.claimValidator(claims); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// audienceContains reports whether want is among the "aud" claim's values.
+// Per RFC 7519 section 4.1.3, "aud" is either a single string or an array
+// of strings; real IdPs (Auth0 among them) commonly issue it as an array.
+func audienceContains(aud interface{}, want string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == want
+	case []string:
+		for _, a := range v {
+			if a == want {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// numericClaim converts a claim decoded from JSON (always float64, per
+// encoding/json) into a Unix timestamp. Claims built by hand, e.g. in
+// tests, occasionally use json.Number or int64 instead, so both are
+// accepted too.
+func numericClaim(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case float64:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return int64(f), nil
+	default:
+		return 0, fmt.Errorf("unsupported claim type %T", v)
+	}
+}
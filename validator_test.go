@@ -0,0 +1,39 @@
+package jwtauth
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TestValidator_ExpFloat64 guards against the original bug where "exp" was
+// type-asserted as int64 while encoding/json always decodes JSON numbers as
+// float64, so the check silently never fired.
+func TestValidator_ExpFloat64(t *testing.T) {
+	var v Validator
+	claims := jwt.MapClaims{"exp": float64(100)}
+
+	if err := v.validate(claims, 200); err == nil {
+		t.Fatal("expected expired token (exp=100, now=200) to be rejected")
+	}
+	if err := v.validate(claims, 50); err != nil {
+		t.Fatalf("expected non-expired token (exp=100, now=50) to be accepted, got %v", err)
+	}
+}
+
+// TestValidator_AudienceArray guards against WithAudience rejecting tokens
+// whose "aud" claim is a JSON array (as Auth0 and others commonly issue),
+// rather than a bare string.
+func TestValidator_AudienceArray(t *testing.T) {
+	v := Validator{audience: "my-api"}
+	claims := jwt.MapClaims{"aud": []interface{}{"my-api", "other-api"}}
+
+	if err := v.validate(claims, 0); err != nil {
+		t.Fatalf("expected array aud containing %q to be accepted, got %v", "my-api", err)
+	}
+
+	claims = jwt.MapClaims{"aud": []interface{}{"other-api"}}
+	if err := v.validate(claims, 0); err == nil {
+		t.Fatal("expected array aud not containing the wanted audience to be rejected")
+	}
+}